@@ -0,0 +1,63 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"bytes"
+	"io"
+	"log"
+)
+
+// levelLoggerImpl is a sentinel level that bypasses both XLogger's and
+// every adapter's level filter, so messages forwarded from a redirected
+// stdlib *log.Logger are never silently dropped.
+const levelLoggerImpl = -1
+
+// logWriter adapts an XLogger, at a fixed level, to io.Writer.
+type logWriter struct {
+	bl    *XLogger
+	level int
+}
+
+// Writer returns an io.Writer that turns every line written to it into a
+// message at level. Pass levelLoggerImpl to bypass level filtering
+// entirely.
+func (bl *XLogger) Writer(level int) io.Writer {
+	return &logWriter{bl: bl, level: level}
+}
+
+// StdLogger wraps Writer(level) in a stdlib *log.Logger with no
+// built-in prefix or flags, so code that only knows about log.Logger
+// (via log.SetOutput or dependency injection) can still log through bl.
+func (bl *XLogger) StdLogger(level int) *log.Logger {
+	return log.New(bl.Writer(level), "", 0)
+}
+
+// Write implements io.Writer. p may contain several lines (as the stdlib
+// log package hands over one Printf/Println/Print call at a time); each
+// non-empty line becomes its own XLogger message.
+func (w *logWriter) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+		// Skip two extra frames versus the normal writerMsg path: the
+		// stdlib log package calls Output, which calls this Write, so
+		// without the adjustment the reported caller would be a frame
+		// inside the log package, not the code that logged the line.
+		w.bl.writerMsg(w.level, string(line), 2)
+	}
+	return len(p), nil
+}
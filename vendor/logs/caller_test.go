@@ -0,0 +1,107 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// captureAdapter records the last message handed to WriteMsg, so tests
+// can inspect exactly what XLogger formatted.
+type captureAdapter struct {
+	mu  sync.Mutex
+	msg string
+}
+
+func newCaptureAdapter() XLoggerInterface { return &captureAdapter{} }
+
+func (c *captureAdapter) Init(string) error { return nil }
+
+func (c *captureAdapter) WriteMsg(msg string, level int) error {
+	c.mu.Lock()
+	c.msg = msg
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *captureAdapter) Destroy() {}
+func (c *captureAdapter) Flush()   {}
+
+func (c *captureAdapter) last() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.msg
+}
+
+func init() {
+	Register("capture_test", newCaptureAdapter)
+}
+
+// TestCallerDepthReportsRealCaller guards against regressing the
+// func-call-depth reporting added in chunk0-1/chunk0-2 when chunk0-6
+// introduced writerMsg's extraSkip parameter: an ordinary call like
+// Info() must still report its own call site, not a frame inside
+// XLogger itself.
+func TestCallerDepthReportsRealCaller(t *testing.T) {
+	bl := NewXLogger(10)
+	if err := bl.SetXLogger("capture_test", ""); err != nil {
+		t.Fatal(err)
+	}
+	bl.EnableFuncCallDepth(true)
+
+	bl.Info("hello") // caller line under test
+	_, file, line, _ := runtime.Caller(0)
+	line-- // runtime.Caller(0) above is the line right after the call
+
+	adapter := bl.outputs["capture_test"].(*captureAdapter)
+	msg := adapter.last()
+
+	_, filename := path.Split(file)
+	want := fmt.Sprintf(":%s:%d]", filename, line)
+	if !strings.Contains(msg, want) {
+		t.Fatalf("writerMsg reported the wrong caller: got %q, want it to contain %q", msg, want)
+	}
+}
+
+// TestWriterBridgeReportsRealCaller covers the bridge's own extraSkip
+// adjustment: a message sent through StdLogger must report the call
+// site that invoked the stdlib logger, not a frame inside log.Output or
+// logWriter.Write.
+func TestWriterBridgeReportsRealCaller(t *testing.T) {
+	bl := NewXLogger(10)
+	if err := bl.SetXLogger("capture_test", ""); err != nil {
+		t.Fatal(err)
+	}
+	bl.EnableFuncCallDepth(true)
+
+	std := bl.StdLogger(levelLoggerImpl)
+	std.Println("hello")
+	_, file, line, _ := runtime.Caller(0)
+	line--
+
+	adapter := bl.outputs["capture_test"].(*captureAdapter)
+	msg := adapter.last()
+
+	_, filename := path.Split(file)
+	want := fmt.Sprintf(":%s:%d]", filename, line)
+	if !strings.Contains(msg, want) {
+		t.Fatalf("bridge reported the wrong caller: got %q, want it to contain %q", msg, want)
+	}
+}
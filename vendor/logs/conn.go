@@ -0,0 +1,128 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+)
+
+// connWriter writes log messages to a TCP or UDP socket, dialing lazily
+// and, when configured, reconnecting on write errors. It is registered
+// under the name "conn".
+type connWriter struct {
+	Reconnect      bool   `json:"reconnect"`
+	ReconnectOnMsg bool   `json:"reconnectOnMsg"`
+	Net            string `json:"net"`
+	Addr           string `json:"addr"`
+	Level          int    `json:"level"`
+	Format         string `json:"format"`
+
+	formatter Formatter
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewConn creates a conn adapter. config must set at least "net"
+// ("tcp"/"udp") and "addr".
+func NewConn() XLoggerInterface {
+	return &connWriter{Level: LevelDebug, formatter: TextFormatter{}}
+}
+
+func (c *connWriter) Init(jsonconfig string) error {
+	if len(jsonconfig) > 0 {
+		if err := json.Unmarshal([]byte(jsonconfig), c); err != nil {
+			return err
+		}
+	}
+	if c.Format != "" {
+		f, err := ParseFormatter(c.Format)
+		if err != nil {
+			return err
+		}
+		c.formatter = f
+	}
+	return nil
+}
+
+func (c *connWriter) WriteMsg(msg string, level int) error {
+	if level > c.Level {
+		return nil
+	}
+	return c.write([]byte(msg + "\n"))
+}
+
+// WriteRecord implements StructuredWriter.
+func (c *connWriter) WriteRecord(rec *LogRecord) error {
+	if rec.Level > c.Level {
+		return nil
+	}
+	return c.write(append(c.formatter.Format(rec), '\n'))
+}
+
+func (c *connWriter) write(b []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		if err := c.dial(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := c.conn.Write(b); err != nil {
+		if !c.Reconnect {
+			return err
+		}
+		c.conn.Close()
+		c.conn = nil
+		if err := c.dial(); err != nil {
+			return err
+		}
+		if _, err := c.conn.Write(b); err != nil {
+			return err
+		}
+	} else if c.ReconnectOnMsg {
+		c.conn.Close()
+		c.conn = nil
+	}
+	return nil
+}
+
+func (c *connWriter) dial() error {
+	conn, err := net.Dial(c.Net, c.Addr)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	return nil
+}
+
+func (c *connWriter) Flush() {}
+
+func (c *connWriter) Destroy() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+}
+
+func init() {
+	Register("conn", NewConn)
+}
@@ -0,0 +1,92 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// consoleConfig is the JSON config accepted by the console adapter.
+type consoleConfig struct {
+	Level  int    `json:"level"`
+	JSON   bool   `json:"json"`
+	Format string `json:"format"`
+}
+
+// consoleWriter writes log messages to stdout. It implements
+// StructuredWriter so loggers with contextual fields attached via
+// WithField/WithFields are rendered through formatter instead of losing
+// those fields to the plain WriteMsg fallback.
+type consoleWriter struct {
+	level     int
+	formatter Formatter
+}
+
+// NewConsole creates a console adapter, registered under the name
+// "console". config is optional JSON, e.g.
+// {"level":2,"format":"[%Date %Time] [%LEV] %Msg%n"}.
+func NewConsole() XLoggerInterface {
+	return &consoleWriter{level: LevelDebug, formatter: TextFormatter{}}
+}
+
+func (c *consoleWriter) Init(config string) error {
+	if len(config) == 0 {
+		return nil
+	}
+	cfg := &consoleConfig{Level: LevelDebug}
+	if err := json.Unmarshal([]byte(config), cfg); err != nil {
+		return err
+	}
+	c.level = cfg.Level
+	switch {
+	case cfg.Format != "":
+		f, err := ParseFormatter(cfg.Format)
+		if err != nil {
+			return err
+		}
+		c.formatter = f
+	case cfg.JSON:
+		c.formatter = JSONFormatter{}
+	}
+	return nil
+}
+
+func (c *consoleWriter) WriteMsg(msg string, level int) error {
+	if level > c.level {
+		return nil
+	}
+	_, err := fmt.Println(msg)
+	return err
+}
+
+// WriteRecord implements StructuredWriter.
+func (c *consoleWriter) WriteRecord(rec *LogRecord) error {
+	if rec.Level > c.level {
+		return nil
+	}
+	_, err := fmt.Println(string(c.formatter.Format(rec)))
+	return err
+}
+
+func (c *consoleWriter) Destroy() {
+}
+
+func (c *consoleWriter) Flush() {
+}
+
+func init() {
+	Register("console", NewConsole)
+}
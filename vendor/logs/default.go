@@ -0,0 +1,108 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import "fmt"
+
+// defaultLogger is the package-level logger used by the top-level
+// Emergency/Alert/.../Trace functions below. It has "console" registered
+// out of the box, so logs.Info("hi") works with zero setup.
+var defaultLogger = NewXLogger(1000)
+
+func init() {
+	if err := defaultLogger.SetXLogger("console", ""); err != nil {
+		fmt.Println("logs: failed to register default console adapter:", err)
+	}
+}
+
+// SetLogger configures adapter on the default logger. Unlike
+// XLogger.SetXLogger, calling it twice for the same adapter reconfigures
+// it by destroying the previous instance and installing a fresh one,
+// rather than leaking the previous instance.
+func SetLogger(adapter string, config string) error {
+	_ = defaultLogger.DelXLogger(adapter)
+	return defaultLogger.SetXLogger(adapter, config)
+}
+
+// SetLevel sets the level of the default logger.
+func SetLevel(l int) {
+	defaultLogger.SetLevel(l)
+}
+
+// EnableFuncCallDepth toggles caller reporting on the default logger.
+func EnableFuncCallDepth(b bool) {
+	defaultLogger.EnableFuncCallDepth(b)
+}
+
+// Async makes the default logger asynchronous. See XLogger.Async.
+func Async() {
+	defaultLogger.Async()
+}
+
+// Flush flushes the default logger.
+func Flush() {
+	defaultLogger.Flush()
+}
+
+// Close closes the default logger.
+func Close() {
+	defaultLogger.Close()
+}
+
+// Emergency logs a message at LevelEmergency on the default logger.
+func Emergency(format string, v ...interface{}) {
+	defaultLogger.Emergency(format, v...)
+}
+
+// Alert logs a message at LevelAlert on the default logger.
+func Alert(format string, v ...interface{}) {
+	defaultLogger.Alert(format, v...)
+}
+
+// Critical logs a message at LevelCritical on the default logger.
+func Critical(format string, v ...interface{}) {
+	defaultLogger.Critical(format, v...)
+}
+
+// Error logs a message at LevelError on the default logger.
+func Error(format string, v ...interface{}) {
+	defaultLogger.Error(format, v...)
+}
+
+// Warning logs a message at LevelWarning on the default logger.
+func Warning(format string, v ...interface{}) {
+	defaultLogger.Warning(format, v...)
+}
+
+// Notice logs a message at LevelNotice on the default logger.
+func Notice(format string, v ...interface{}) {
+	defaultLogger.Notice(format, v...)
+}
+
+// Info logs a message at LevelInformational on the default logger.
+func Info(format string, v ...interface{}) {
+	defaultLogger.Info(format, v...)
+}
+
+// Debug logs a message at LevelDebug on the default logger.
+func Debug(format string, v ...interface{}) {
+	defaultLogger.Debug(format, v...)
+}
+
+// Trace logs a message at LevelDebug on the default logger.
+// compatibility alias for Debug()
+func Trace(format string, v ...interface{}) {
+	defaultLogger.Trace(format, v...)
+}
@@ -0,0 +1,61 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import "testing"
+
+func TestDefaultLoggerHasConsoleRegisteredOutOfTheBox(t *testing.T) {
+	if _, ok := defaultLogger.outputs["console"]; !ok {
+		t.Fatal("expected the default logger to have \"console\" registered at init time")
+	}
+}
+
+// TestSetLoggerReplacesExistingAdapter guards SetLogger's documented
+// difference from XLogger.SetXLogger: calling it twice for the same
+// adapter must replace the instance, not error out or leak the old one.
+func TestSetLoggerReplacesExistingAdapter(t *testing.T) {
+	if err := SetLogger("capture_test", ""); err != nil {
+		t.Fatal(err)
+	}
+	first := defaultLogger.outputs["capture_test"]
+
+	if err := SetLogger("capture_test", ""); err != nil {
+		t.Fatalf("second SetLogger call for the same adapter should not error: %v", err)
+	}
+	second := defaultLogger.outputs["capture_test"]
+
+	if first == second {
+		t.Fatal("expected the second SetLogger call to install a fresh adapter instance")
+	}
+
+	_ = defaultLogger.DelXLogger("capture_test")
+}
+
+func TestPackageLevelHelpersLogThroughDefaultLogger(t *testing.T) {
+	if err := SetLogger("record_capture_test", ""); err != nil {
+		t.Fatal(err)
+	}
+	defer defaultLogger.DelXLogger("record_capture_test")
+
+	Info("hello %s", "world")
+
+	adapter := defaultLogger.outputs["record_capture_test"].(*recordCaptureAdapter)
+	if adapter.rec == nil {
+		t.Fatal("expected Info() to have written a record through the default logger")
+	}
+	if adapter.rec.Msg != "[I] hello world" {
+		t.Fatalf("rec.Msg = %q, want %q", adapter.rec.Msg, "[I] hello world")
+	}
+}
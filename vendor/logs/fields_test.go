@@ -0,0 +1,112 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// recordCaptureAdapter implements StructuredWriter and records the last
+// LogRecord handed to it, so tests can inspect fields exactly as XLogger
+// assembled them rather than through a formatted string.
+type recordCaptureAdapter struct {
+	rec *LogRecord
+}
+
+func newRecordCaptureAdapter() XLoggerInterface { return &recordCaptureAdapter{} }
+
+func (r *recordCaptureAdapter) Init(string) error          { return nil }
+func (r *recordCaptureAdapter) WriteMsg(string, int) error { return nil }
+func (r *recordCaptureAdapter) Destroy()                   {}
+func (r *recordCaptureAdapter) Flush()                     {}
+func (r *recordCaptureAdapter) WriteRecord(rec *LogRecord) error {
+	r.rec = rec
+	return nil
+}
+
+func init() {
+	Register("record_capture_test", newRecordCaptureAdapter)
+}
+
+// TestWithFieldsMergesAndLeavesParentUntouched covers chunk0-1's WithField/
+// WithFields: a child logger must carry the parent's fields plus its own,
+// and logging through the child must never mutate the parent's fields.
+func TestWithFieldsMergesAndLeavesParentUntouched(t *testing.T) {
+	bl := NewXLogger(10)
+	if err := bl.SetXLogger("record_capture_test", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := bl.WithField("service", "gobog")
+	child := parent.WithFields(map[string]interface{}{"request_id": "abc123"})
+
+	child.Info("hello")
+
+	adapter := bl.outputs["record_capture_test"].(*recordCaptureAdapter)
+	rec := adapter.rec
+	if rec == nil {
+		t.Fatal("expected a record to have been captured")
+	}
+	if rec.Fields["service"] != "gobog" || rec.Fields["request_id"] != "abc123" {
+		t.Fatalf("child record fields = %v, want service and request_id set", rec.Fields)
+	}
+
+	parent.Info("parent only")
+	rec = adapter.rec
+	if _, ok := rec.Fields["request_id"]; ok {
+		t.Fatalf("parent record leaked child's field: %v", rec.Fields)
+	}
+}
+
+// TestWithErrorStoresMessageNotRawError guards against the raw error
+// value being stored in Fields, which would marshal to "{}" for any
+// error type without exported fields (errors.errorString, fmt.wrapError).
+func TestWithErrorStoresMessageNotRawError(t *testing.T) {
+	bl := NewXLogger(10)
+	if err := bl.SetXLogger("record_capture_test", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	bl.WithError(errors.New("boom")).Error("failed")
+
+	adapter := bl.outputs["record_capture_test"].(*recordCaptureAdapter)
+	rec := adapter.rec
+	if rec == nil {
+		t.Fatal("expected a record to have been captured")
+	}
+	got, ok := rec.Fields["error"].(string)
+	if !ok {
+		t.Fatalf("Fields[%q] = %#v (%T), want a string", "error", rec.Fields["error"], rec.Fields["error"])
+	}
+	if !strings.Contains(got, "boom") {
+		t.Fatalf("error field = %q, want it to contain %q", got, "boom")
+	}
+}
+
+// TestJSONFormatterRendersErrorMessage is the end-to-end regression test
+// for the bug itself: WithError through JSONFormatter must produce a
+// usable "error" string, not "{}".
+func TestJSONFormatterRendersErrorMessage(t *testing.T) {
+	rec := &LogRecord{
+		Msg:    "failed",
+		Fields: map[string]interface{}{"error": errors.New("boom").Error()},
+	}
+	out := string(JSONFormatter{}.Format(rec))
+	if !strings.Contains(out, `"error":"boom"`) {
+		t.Fatalf("JSONFormatter output = %s, want it to contain an \"error\":\"boom\" field", out)
+	}
+}
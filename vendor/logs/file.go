@@ -0,0 +1,271 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileLogWriter writes log messages to a file, rotating it by line count,
+// byte size, and/or calendar day, and pruning rotated files older than
+// Maxdays. It is registered under the name "file".
+type fileLogWriter struct {
+	sync.RWMutex
+
+	Filename string `json:"filename"`
+	Maxlines int    `json:"maxlines"`
+	Maxsize  int    `json:"maxsize"`
+	Daily    bool   `json:"daily"`
+	Maxdays  int64  `json:"maxdays"`
+	Rotate   bool   `json:"rotate"`
+	Perm     string `json:"perm"`
+	Level    int    `json:"level"`
+	Format   string `json:"format"`
+
+	fileWriter *os.File
+	formatter  Formatter
+
+	maxlinesCurLines int
+	maxsizeCurSize   int
+	dailyOpenDate    int
+
+	fileNameOnly, suffix string // filename split on its extension
+}
+
+// NewFileWriter creates a file adapter with the repo's defaults: daily
+// rotation, a 256MB size cap, and a week of retention. Init's JSON config
+// overrides any of these.
+func NewFileWriter() XLoggerInterface {
+	return &fileLogWriter{
+		Maxlines:  1000000,
+		Maxsize:   1 << 28, // 256 MB
+		Daily:     true,
+		Maxdays:   7,
+		Rotate:    true,
+		Perm:      "0660",
+		Level:     LevelDebug,
+		formatter: TextFormatter{},
+	}
+}
+
+func (w *fileLogWriter) Init(jsonconfig string) error {
+	if len(jsonconfig) > 0 {
+		if err := json.Unmarshal([]byte(jsonconfig), w); err != nil {
+			return err
+		}
+	}
+	if len(w.Filename) == 0 {
+		return fmt.Errorf("logs.fileLogWriter: filename cannot be empty")
+	}
+	if w.Format != "" {
+		f, err := ParseFormatter(w.Format)
+		if err != nil {
+			return err
+		}
+		w.formatter = f
+	}
+	w.suffix = filepath.Ext(w.Filename)
+	w.fileNameOnly = strings.TrimSuffix(w.Filename, w.suffix)
+	if w.suffix == "" {
+		w.suffix = ".log"
+	}
+	return w.startLogger()
+}
+
+func (w *fileLogWriter) startLogger() error {
+	file, err := w.createOrOpenFile()
+	if err != nil {
+		return err
+	}
+	if w.fileWriter != nil {
+		w.fileWriter.Close()
+	}
+	w.fileWriter = file
+	return w.restoreCounters()
+}
+
+func (w *fileLogWriter) createOrOpenFile() (*os.File, error) {
+	perm, err := strconv.ParseInt(w.Perm, 8, 64)
+	if err != nil {
+		perm = 0660
+	}
+	file, err := os.OpenFile(w.Filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, os.FileMode(perm))
+	if err != nil {
+		return nil, err
+	}
+	os.Chmod(w.Filename, os.FileMode(perm))
+	return file, nil
+}
+
+// restoreCounters recomputes the line/byte counters from an existing file
+// on disk, so a process restart doesn't reset the rotation thresholds.
+func (w *fileLogWriter) restoreCounters() error {
+	info, err := w.fileWriter.Stat()
+	if err != nil {
+		return err
+	}
+	w.maxsizeCurSize = int(info.Size())
+	w.dailyOpenDate = time.Now().Day()
+	w.maxlinesCurLines = 0
+	if w.Maxlines > 0 {
+		if data, err := os.ReadFile(w.Filename); err == nil {
+			w.maxlinesCurLines = bytes.Count(data, []byte{'\n'})
+		}
+	}
+	return nil
+}
+
+func (w *fileLogWriter) WriteMsg(msg string, level int) error {
+	if level > w.Level {
+		return nil
+	}
+	return w.write([]byte(msg+"\n"), level)
+}
+
+// WriteRecord implements StructuredWriter.
+func (w *fileLogWriter) WriteRecord(rec *LogRecord) error {
+	if rec.Level > w.Level {
+		return nil
+	}
+	return w.write(append(w.formatter.Format(rec), '\n'), rec.Level)
+}
+
+func (w *fileLogWriter) write(data []byte, level int) error {
+	if w.Rotate {
+		w.RLock()
+		needRotate := w.needRotate(len(data))
+		w.RUnlock()
+		if needRotate {
+			w.Lock()
+			if w.needRotate(len(data)) {
+				if err := w.doRotate(time.Now()); err != nil {
+					fmt.Fprintf(os.Stderr, "logs.fileLogWriter.DoRotate: %s\n", err)
+				}
+			}
+			w.Unlock()
+		}
+	}
+
+	w.Lock()
+	defer w.Unlock()
+	_, err := w.fileWriter.Write(data)
+	if err == nil {
+		w.maxlinesCurLines++
+		w.maxsizeCurSize += len(data)
+	}
+	return err
+}
+
+func (w *fileLogWriter) needRotate(size int) bool {
+	return (w.Maxlines > 0 && w.maxlinesCurLines >= w.Maxlines) ||
+		(w.Maxsize > 0 && w.maxsizeCurSize+size >= w.Maxsize) ||
+		(w.Daily && time.Now().Day() != w.dailyOpenDate)
+}
+
+// DoRotate lets callers (e.g. a SIGHUP handler) force a rotation outside
+// the normal size/line/day triggers.
+func (w *fileLogWriter) DoRotate() error {
+	w.Lock()
+	defer w.Unlock()
+	return w.doRotate(time.Now())
+}
+
+// doRotate renames the active file to name.YYYY-MM-DD.NNN.ext, the first
+// number not already taken, then reopens Filename fresh. Callers must
+// hold w's write lock.
+func (w *fileLogWriter) doRotate(now time.Time) error {
+	_, statErr := os.Lstat(w.Filename)
+	if statErr == nil {
+		var newFilename string
+		num := 1
+		for ; statErr == nil && num <= 999; num++ {
+			newFilename = fmt.Sprintf("%s.%s.%03d%s", w.fileNameOnly, now.Format("2006-01-02"), num, w.suffix)
+			_, statErr = os.Lstat(newFilename)
+		}
+		if statErr == nil {
+			return fmt.Errorf("logs.fileLogWriter: cannot find a free rotated name for %s", w.Filename)
+		}
+		w.fileWriter.Close()
+		if err := os.Rename(w.Filename, newFilename); err != nil {
+			return err
+		}
+	}
+
+	file, err := w.createOrOpenFile()
+	if err != nil {
+		return err
+	}
+	w.fileWriter = file
+	w.maxlinesCurLines = 0
+	w.maxsizeCurSize = 0
+	w.dailyOpenDate = now.Day()
+
+	go w.deleteOldLog()
+	return nil
+}
+
+// deleteOldLog prunes rotated files belonging to this writer that are
+// older than Maxdays. It runs in its own goroutine so rotation isn't
+// blocked on directory scanning.
+func (w *fileLogWriter) deleteOldLog() {
+	if w.Maxdays <= 0 {
+		return
+	}
+	dir := filepath.Dir(w.Filename)
+	cutoff := time.Now().Add(-time.Duration(w.Maxdays) * 24 * time.Hour)
+	base := filepath.Base(w.fileNameOnly)
+
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		defer func() {
+			if r := recover(); r != nil {
+				fmt.Fprintf(os.Stderr, "logs.fileLogWriter.deleteOldLog recovered: %v\n", r)
+			}
+		}()
+		if err != nil || info == nil || info.IsDir() {
+			return nil
+		}
+		if !strings.HasPrefix(filepath.Base(path), base) || !strings.HasSuffix(path, w.suffix) {
+			return nil
+		}
+		if info.ModTime().Before(cutoff) {
+			os.Remove(path)
+		}
+		return nil
+	})
+}
+
+func (w *fileLogWriter) Flush() {
+	w.Lock()
+	defer w.Unlock()
+	w.fileWriter.Sync()
+}
+
+func (w *fileLogWriter) Destroy() {
+	w.Lock()
+	defer w.Unlock()
+	w.fileWriter.Close()
+}
+
+func init() {
+	Register("file", NewFileWriter)
+}
@@ -0,0 +1,122 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileWriterRotatesOnMaxlinesAndNamesByDate(t *testing.T) {
+	dir := t.TempDir()
+	logfile := filepath.Join(dir, "app.log")
+
+	w := NewFileWriter().(*fileLogWriter)
+	cfg := fmt.Sprintf(`{"filename":%q,"maxlines":3,"daily":false,"maxsize":0}`, logfile)
+	if err := w.Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Destroy()
+
+	for i := 0; i < 5; i++ {
+		if err := w.WriteMsg(fmt.Sprintf("line %d", i), LevelInfo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Flush()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rotated string
+	for _, e := range entries {
+		if e.Name() != "app.log" {
+			rotated = e.Name()
+		}
+	}
+	if rotated == "" {
+		t.Fatalf("expected a rotated file alongside app.log, got entries: %v", entries)
+	}
+	wantPrefix := "app." + time.Now().Format("2006-01-02") + "."
+	if len(rotated) < len(wantPrefix) || rotated[:len(wantPrefix)] != wantPrefix {
+		t.Fatalf("rotated file %q does not match expected name.date.NNN.ext pattern %q*", rotated, wantPrefix)
+	}
+}
+
+func TestFileWriterRestoresCountersOnReopen(t *testing.T) {
+	dir := t.TempDir()
+	logfile := filepath.Join(dir, "app.log")
+	cfg := fmt.Sprintf(`{"filename":%q,"maxlines":1000,"daily":false,"maxsize":0}`, logfile)
+
+	w1 := NewFileWriter().(*fileLogWriter)
+	if err := w1.Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if err := w1.WriteMsg("hello", LevelInfo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w1.Destroy()
+
+	w2 := NewFileWriter().(*fileLogWriter)
+	if err := w2.Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	defer w2.Destroy()
+
+	if w2.maxlinesCurLines != 4 {
+		t.Fatalf("maxlinesCurLines after reopen = %d, want 4", w2.maxlinesCurLines)
+	}
+}
+
+func TestFileWriterPrunesFilesOlderThanMaxdays(t *testing.T) {
+	dir := t.TempDir()
+	logfile := filepath.Join(dir, "app.log")
+
+	// Pre-seed an old rotated file that should be pruned, and one recent
+	// rotated file that should survive.
+	old := filepath.Join(dir, "app.2000-01-01.001.log")
+	recent := filepath.Join(dir, "app.2000-01-02.001.log")
+	for _, p := range []string{old, recent} {
+		if err := os.WriteFile(p, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldTime := time.Now().Add(-30 * 24 * time.Hour)
+	if err := os.Chtimes(old, oldTime, oldTime); err != nil {
+		t.Fatal(err)
+	}
+
+	w := NewFileWriter().(*fileLogWriter)
+	cfg := fmt.Sprintf(`{"filename":%q,"maxdays":7}`, logfile)
+	if err := w.Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Destroy()
+
+	w.deleteOldLog()
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be pruned, stat err = %v", old, err)
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Fatalf("expected %s to survive pruning, stat err = %v", recent, err)
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Formatter renders a LogRecord into the bytes an adapter writes to its
+// underlying sink.
+type Formatter interface {
+	Format(rec *LogRecord) []byte
+}
+
+var levelNames = [...]string{"M", "A", "C", "E", "W", "N", "I", "D"}
+
+func levelName(level int) string {
+	if level < 0 || level >= len(levelNames) {
+		return "?"
+	}
+	return levelNames[level]
+}
+
+// TextFormatter renders a record using the original bracketed layout:
+// [extra:file:line] msg key=value .... It is the default formatter for
+// every adapter.
+type TextFormatter struct{}
+
+// Format implements Formatter.
+func (TextFormatter) Format(rec *LogRecord) []byte {
+	var buf bytes.Buffer
+	if rec.File != "" {
+		fmt.Fprintf(&buf, "[%s:%s:%d] %s", rec.Extra, rec.File, rec.Line, rec.Msg)
+	} else {
+		fmt.Fprintf(&buf, "[%s] %s", rec.Extra, rec.Msg)
+	}
+	for k, v := range rec.Fields {
+		fmt.Fprintf(&buf, " %s=%v", k, v)
+	}
+	return buf.Bytes()
+}
+
+// JSONFormatter renders a record as a single line of JSON.
+type JSONFormatter struct{}
+
+// Format implements Formatter.
+func (JSONFormatter) Format(rec *LogRecord) []byte {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return []byte(err.Error())
+	}
+	return b
+}
+
+var (
+	customFormattersLock sync.RWMutex
+	customFormatters     = make(map[string]func(rec *LogRecord) string)
+)
+
+// RegisterCustomFormatter makes fn available to PatternFormatter patterns
+// under the verb %CustomN(name).
+func RegisterCustomFormatter(name string, fn func(rec *LogRecord) string) {
+	customFormattersLock.Lock()
+	defer customFormattersLock.Unlock()
+	customFormatters[name] = fn
+}
+
+func lookupCustomFormatter(name string) (func(rec *LogRecord) string, bool) {
+	customFormattersLock.RLock()
+	defer customFormattersLock.RUnlock()
+	fn, ok := customFormatters[name]
+	return fn, ok
+}
+
+// patternSegment renders one piece of a compiled pattern.
+type patternSegment func(rec *LogRecord) string
+
+// PatternFormatter renders a record using a seelog-style verb pattern,
+// e.g. "[%Date %Time] [%LEV] %Msg (%File:%Line)%n". The pattern is
+// compiled once, at construction time, into a slice of segment functions
+// so rendering a message is just a walk over that slice.
+type PatternFormatter struct {
+	segments []patternSegment
+}
+
+// NewPatternFormatter compiles pattern and returns a ready-to-use
+// PatternFormatter, or an error if pattern references an unknown verb.
+func NewPatternFormatter(pattern string) (*PatternFormatter, error) {
+	segments, err := compilePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &PatternFormatter{segments: segments}, nil
+}
+
+// Format implements Formatter.
+func (p *PatternFormatter) Format(rec *LogRecord) []byte {
+	var buf bytes.Buffer
+	for _, seg := range p.segments {
+		buf.WriteString(seg(rec))
+	}
+	return buf.Bytes()
+}
+
+// ParseFormatter resolves a "format" config value into a Formatter. The
+// special values "" and "text" mean TextFormatter, "json" means
+// JSONFormatter, and anything else is compiled as a PatternFormatter.
+func ParseFormatter(spec string) (Formatter, error) {
+	switch spec {
+	case "", "text":
+		return TextFormatter{}, nil
+	case "json":
+		return JSONFormatter{}, nil
+	default:
+		return NewPatternFormatter(spec)
+	}
+}
+
+func literalSegment(s string) patternSegment {
+	return func(*LogRecord) string { return s }
+}
+
+// knownVerbs lists recognised verb names, longest first, so that e.g.
+// "Level" is matched before its prefix "Lev" while scanning.
+var knownVerbs = []string{
+	"FuncShort", "FullPath", "CustomN", "Level", "Extra",
+	"Date", "Time", "File", "Line", "LEV", "Lev", "Msg", "Ns", "n", "t",
+}
+
+// compilePattern parses pattern into a slice of segment funcs. %% is
+// escaped to a literal %; any other %Verb not in knownVerbs is an error.
+func compilePattern(pattern string) ([]patternSegment, error) {
+	var segments []patternSegment
+	var lit strings.Builder
+	flush := func() {
+		if lit.Len() > 0 {
+			segments = append(segments, literalSegment(lit.String()))
+			lit.Reset()
+		}
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' {
+			lit.WriteByte(pattern[i])
+			continue
+		}
+		if i+1 < len(pattern) && pattern[i+1] == '%' {
+			lit.WriteByte('%')
+			i++
+			continue
+		}
+		verb, arg, consumed := scanVerb(pattern[i+1:])
+		if verb == "" {
+			return nil, fmt.Errorf("logs: unknown verb at offset %d in pattern %q", i, pattern)
+		}
+		seg, err := verbSegment(verb, arg)
+		if err != nil {
+			return nil, err
+		}
+		flush()
+		segments = append(segments, seg)
+		i += consumed
+	}
+	flush()
+	return segments, nil
+}
+
+// scanVerb finds the longest verb in knownVerbs at the start of rest
+// (rest being everything right after the '%'), along with the
+// parenthesised argument of a %CustomN(name) verb. It returns how many
+// bytes of rest were consumed by the verb (and its argument, if any).
+func scanVerb(rest string) (verb, arg string, consumed int) {
+	for _, name := range knownVerbs {
+		if !strings.HasPrefix(rest, name) {
+			continue
+		}
+		consumed = len(name)
+		if name == "CustomN" && consumed < len(rest) && rest[consumed] == '(' {
+			if end := strings.IndexByte(rest[consumed:], ')'); end >= 0 {
+				arg = rest[consumed+1 : consumed+end]
+				consumed += end + 1
+			}
+		}
+		return name, arg, consumed
+	}
+	return "", "", 0
+}
+
+func verbSegment(verb, arg string) (patternSegment, error) {
+	switch verb {
+	case "Date":
+		return func(rec *LogRecord) string { return rec.Time.Format("2006-01-02") }, nil
+	case "Time":
+		return func(rec *LogRecord) string { return rec.Time.Format("15:04:05") }, nil
+	case "Ns":
+		return func(rec *LogRecord) string { return strconv.Itoa(rec.Time.Nanosecond()) }, nil
+	case "Level", "LEV":
+		return func(rec *LogRecord) string { return strings.ToUpper(levelName(rec.Level)) }, nil
+	case "Lev":
+		return func(rec *LogRecord) string { return levelName(rec.Level) }, nil
+	case "Msg":
+		return func(rec *LogRecord) string { return rec.Msg }, nil
+	case "File":
+		return func(rec *LogRecord) string { return rec.File }, nil
+	case "FullPath":
+		return func(rec *LogRecord) string { return rec.FullPath }, nil
+	case "Line":
+		return func(rec *LogRecord) string { return strconv.Itoa(rec.Line) }, nil
+	case "FuncShort":
+		return func(rec *LogRecord) string {
+			name := rec.Func
+			if idx := strings.LastIndexByte(name, '/'); idx >= 0 {
+				name = name[idx+1:]
+			}
+			return name
+		}, nil
+	case "Extra":
+		return func(rec *LogRecord) string { return rec.Extra }, nil
+	case "n":
+		return func(*LogRecord) string { return "\n" }, nil
+	case "t":
+		return func(*LogRecord) string { return "\t" }, nil
+	case "CustomN":
+		return func(rec *LogRecord) string {
+			fn, ok := lookupCustomFormatter(arg)
+			if !ok {
+				return ""
+			}
+			return fn(rec)
+		}, nil
+	default:
+		return nil, fmt.Errorf("logs: unknown verb %%%s", verb)
+	}
+}
@@ -37,6 +37,8 @@ import (
 	"path"
 	"runtime"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // RFC5424 log message levels.
@@ -70,6 +72,31 @@ type XLoggerInterface interface {
 	Flush()
 }
 
+// StructuredWriter is an optional interface a log provider can implement
+// in addition to XLoggerInterface. When an adapter satisfies it, XLogger
+// hands it the full LogRecord instead of a pre-formatted string, so it can
+// render key=value logfmt, JSON, or whatever machine-parseable shape its
+// downstream consumer expects. Adapters that don't implement it keep
+// receiving the formatted fallback via WriteMsg.
+type StructuredWriter interface {
+	WriteRecord(rec *LogRecord) error
+}
+
+// LogRecord carries everything known about a single log line: its level,
+// the message, contextual fields attached via WithField/WithFields, and,
+// when func-call-depth reporting is enabled, the caller's location.
+type LogRecord struct {
+	Level    int                    `json:"level"`
+	Time     time.Time              `json:"time"`
+	Msg      string                 `json:"msg"`
+	Extra    string                 `json:"extra,omitempty"`
+	File     string                 `json:"file,omitempty"`
+	FullPath string                 `json:"full_path,omitempty"`
+	Line     int                    `json:"line,omitempty"`
+	Func     string                 `json:"func,omitempty"`
+	Fields   map[string]interface{} `json:"fields,omitempty"`
+}
+
 var adapters = make(map[string]loggerType)
 
 // Register makes a log provide available by the provided name.
@@ -85,6 +112,19 @@ func Register(name string, log loggerType) {
 	adapters[name] = log
 }
 
+// OverflowPolicy controls what an asynchronous XLogger does when its
+// message channel is full.
+type OverflowPolicy int
+
+const (
+	// BlockOnFull blocks the caller until the channel has room. This is
+	// the default, matching the original behavior.
+	BlockOnFull OverflowPolicy = iota
+	// DropOnFull drops the message instead of blocking, counting it in
+	// Dropped() and occasionally surfacing a warning in the log stream.
+	DropOnFull
+)
+
 // XLogger is default logger in beego application.
 // it can contain several providers and log message into all providers.
 type XLogger struct {
@@ -93,14 +133,75 @@ type XLogger struct {
 	enableFuncCallDepth bool
 	loggerFuncCallDepth int
 	asynchronous        bool
+	overflow            OverflowPolicy
+	dropped             uint64
 	msg                 chan *logMsg
+	signalChan          chan struct{}
+	drain               *drainCounter
 	outputs             map[string]XLoggerInterface
 	extra				string
+	fields              map[string]interface{}
 }
 
 type logMsg struct {
-	level int
-	msg   string
+	level  int
+	msg    string
+	record *LogRecord
+}
+
+// logMsgPool recycles logMsg values across log calls so enqueueing a
+// message under load doesn't allocate. A value is taken out in
+// writerMsg and returned once it has been handed to every adapter.
+var logMsgPool = sync.Pool{
+	New: func() interface{} { return new(logMsg) },
+}
+
+// drainCounter tracks how many enqueued messages an async XLogger still
+// has in flight, so Flush/Close can wait for the pipeline to empty.
+//
+// A sync.WaitGroup can't be used for this: its docs forbid a positive
+// Add racing a Wait whose counter has just reached zero, which is
+// exactly what happens here (logging goroutines keep calling enqueue
+// while Flush/Close call wait, well after startup). drainCounter instead
+// serializes increments, decrements, and the wait loop behind one mutex,
+// so a late Add after pending has hit zero simply makes the next wait
+// block again instead of racing a panic.
+type drainCounter struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending int
+}
+
+func newDrainCounter() *drainCounter {
+	d := &drainCounter{}
+	d.cond = sync.NewCond(&d.mu)
+	return d
+}
+
+func (d *drainCounter) add() {
+	d.mu.Lock()
+	d.pending++
+	d.mu.Unlock()
+}
+
+func (d *drainCounter) done() {
+	d.mu.Lock()
+	d.pending--
+	if d.pending <= 0 {
+		d.cond.Broadcast()
+	}
+	d.mu.Unlock()
+}
+
+// wait blocks until pending drops to zero, as observed at some point
+// during the call. Messages enqueued concurrently with wait may or may
+// not be waited on; that's inherent to flushing while still logging.
+func (d *drainCounter) wait() {
+	d.mu.Lock()
+	for d.pending > 0 {
+		d.cond.Wait()
+	}
+	d.mu.Unlock()
 }
 
 // NewXLogger returns a new XLogger.
@@ -111,6 +212,8 @@ func NewXLogger(channellen int64) *XLogger {
 	bl.level = LevelDebug
 	bl.loggerFuncCallDepth = 2
 	bl.msg = make(chan *logMsg, channellen)
+	bl.signalChan = make(chan struct{})
+	bl.drain = newDrainCounter()
 	bl.outputs = make(map[string]XLoggerInterface)
 	return bl
 }
@@ -121,6 +224,19 @@ func (bl *XLogger) Async() *XLogger {
 	return bl
 }
 
+// SetOverflowPolicy controls what happens when the async message channel
+// is full: block the caller (BlockOnFull, the default) or drop the
+// message (DropOnFull). It has no effect on a synchronous XLogger.
+func (bl *XLogger) SetOverflowPolicy(p OverflowPolicy) *XLogger {
+	bl.overflow = p
+	return bl
+}
+
+// Dropped returns how many messages DropOnFull has discarded so far.
+func (bl *XLogger) Dropped() uint64 {
+	return atomic.LoadUint64(&bl.dropped)
+}
+
 // SetXLogger provides a given logger adapter into XLogger with config string.
 // config need to be correct JSON as string: {"interval":360}.
 func (bl *XLogger) SetXLogger(adaptername string, config string) error {
@@ -153,34 +269,146 @@ func (bl *XLogger) DelXLogger(adaptername string) error {
 	}
 }
 
-func (bl *XLogger) writerMsg(loglevel int, msg string) error {
-	lm := new(logMsg)
-	lm.level = loglevel
+// WithField returns a child logger that attaches key/value, in addition to
+// any fields already carried by bl, to every record it writes from then on.
+// The parent logger is left untouched.
+func (bl *XLogger) WithField(key string, value interface{}) *XLogger {
+	return bl.WithFields(map[string]interface{}{key: value})
+}
+
+// WithFields returns a child logger that attaches fields, in addition to
+// any fields already carried by bl, to every record it writes from then on.
+// The parent logger is left untouched.
+func (bl *XLogger) WithFields(fields map[string]interface{}) *XLogger {
+	merged := make(map[string]interface{}, len(bl.fields)+len(fields))
+	for k, v := range bl.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &XLogger{
+		level:               bl.level,
+		enableFuncCallDepth: bl.enableFuncCallDepth,
+		loggerFuncCallDepth: bl.loggerFuncCallDepth,
+		asynchronous:        bl.asynchronous,
+		overflow:            bl.overflow,
+		msg:                 bl.msg,
+		signalChan:          bl.signalChan,
+		drain:               bl.drain,
+		outputs:             bl.outputs,
+		extra:               bl.extra,
+		fields:              merged,
+	}
+}
+
+// WithError is a shorthand for WithField("error", err.Error()). The message
+// is stored rather than the raw error so it survives JSONFormatter: most
+// error values (errors.errorString, fmt.wrapError, ...) have no exported
+// fields and would otherwise marshal to "{}".
+func (bl *XLogger) WithError(err error) *XLogger {
+	return bl.WithField("error", err.Error())
+}
+
+// writerMsg builds a LogRecord for msg and fans it out to the adapters
+// (synchronously or via enqueue, depending on bl.asynchronous). extraSkip
+// lets a caller that isn't a direct wrapper around writerMsg (namely the
+// io.Writer/stdlib log bridge in bridge.go) account for its own extra
+// stack frames when resolving the caller for func-call-depth reporting;
+// ordinary callers such as Info/Error pass 0.
+func (bl *XLogger) writerMsg(loglevel int, msg string, extraSkip int) error {
+	rec := &LogRecord{
+		Level:  loglevel,
+		Time:   time.Now(),
+		Msg:    msg,
+		Extra:  bl.extra,
+		Fields: bl.fields,
+	}
+	formatted := msg
 	if bl.enableFuncCallDepth {
-		_, file, line, ok := runtime.Caller(bl.loggerFuncCallDepth)
+		pc, file, line, ok := runtime.Caller(bl.loggerFuncCallDepth + extraSkip)
 		if !ok {
 			file = "???"
 			line = 0
 		}
 		_, filename := path.Split(file)
-		lm.msg = fmt.Sprintf("[%s:%s:%d] %s",bl.extra, filename, line, msg)
+		rec.File = filename
+		rec.FullPath = file
+		rec.Line = line
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			rec.Func = fn.Name()
+		}
+		formatted = fmt.Sprintf("[%s:%s:%d] %s", bl.extra, filename, line, msg)
 	} else {
-		lm.msg = fmt.Sprintf("[%s] %s",bl.extra,msg)
+		formatted = fmt.Sprintf("[%s] %s", bl.extra, msg)
 	}
+	lm := logMsgPool.Get().(*logMsg)
+	lm.level = loglevel
+	lm.msg = formatted
+	lm.record = rec
+
 	if bl.asynchronous {
-		bl.msg <- lm
+		bl.enqueue(lm)
 	} else {
-		for name, l := range bl.outputs {
-				//fmt.Println("111111111111", lm.msg)
+		bl.writeToOutputs(lm)
+		logMsgPool.Put(lm)
+	}
+	return nil
+}
 
-			err := l.WriteMsg(lm.msg, lm.level)
-			if err != nil {
-				fmt.Println("unable to WriteMsg to adapter:", name, err)
-				return err
+// enqueue hands lm to the async worker, applying the configured
+// OverflowPolicy when bl.msg is full.
+func (bl *XLogger) enqueue(lm *logMsg) {
+	bl.drain.add()
+	if bl.overflow == DropOnFull {
+		select {
+		case bl.msg <- lm:
+		default:
+			bl.drain.done()
+			logMsgPool.Put(lm)
+			if n := atomic.AddUint64(&bl.dropped, 1); n%1000 == 1 {
+				bl.warnDropped(n)
 			}
 		}
+		return
+	}
+	bl.msg <- lm
+}
+
+// warnDropped injects a warning message into the stream reporting that n
+// messages have been dropped so far. It is itself subject to being
+// dropped, since it is sent with the same non-blocking send as a
+// DropOnFull message.
+func (bl *XLogger) warnDropped(n uint64) {
+	msg := fmt.Sprintf("[W] logs: dropped %d messages because the buffer was full", n)
+	lm := logMsgPool.Get().(*logMsg)
+	lm.level = LevelWarning
+	lm.msg = msg
+	lm.record = &LogRecord{Level: LevelWarning, Time: time.Now(), Msg: msg, Extra: bl.extra}
+
+	bl.drain.add()
+	select {
+	case bl.msg <- lm:
+	default:
+		bl.drain.done()
+		logMsgPool.Put(lm)
+	}
+}
+
+// writeToOutputs fans lm out to every registered adapter, preferring
+// StructuredWriter.WriteRecord over the formatted-string WriteMsg fallback.
+func (bl *XLogger) writeToOutputs(lm *logMsg) {
+	for name, l := range bl.outputs {
+		var err error
+		if sw, ok := l.(StructuredWriter); ok {
+			err = sw.WriteRecord(lm.record)
+		} else {
+			err = l.WriteMsg(lm.msg, lm.level)
+		}
+		if err != nil {
+			fmt.Println("unable to WriteMsg to adapter:", name, err)
+		}
 	}
-	return nil
 }
 
 // Set log message level.
@@ -215,12 +443,11 @@ func (bl *XLogger) startXLogger() {
 	for {
 		select {
 		case bm := <-bl.msg:
-			for _, l := range bl.outputs {
-				err := l.WriteMsg(bm.msg, bm.level)
-				if err != nil {
-					fmt.Println("ERROR, unable to WriteMsg:", err)
-				}
-			}
+			bl.writeToOutputs(bm)
+			logMsgPool.Put(bm)
+			bl.drain.done()
+		case <-bl.signalChan:
+			return
 		}
 	}
 }
@@ -231,7 +458,7 @@ func (bl *XLogger) Emergency(format string, v ...interface{}) {
 		return
 	}
 	msg := fmt.Sprintf("[M] "+format, v...)
-	bl.writerMsg(LevelEmergency, msg)
+	bl.writerMsg(LevelEmergency, msg, 0)
 }
 
 // Log ALERT level message.
@@ -240,7 +467,7 @@ func (bl *XLogger) Alert(format string, v ...interface{}) {
 		return
 	}
 	msg := fmt.Sprintf("[A] "+format, v...)
-	bl.writerMsg(LevelAlert, msg)
+	bl.writerMsg(LevelAlert, msg, 0)
 }
 
 // Log CRITICAL level message.
@@ -249,7 +476,7 @@ func (bl *XLogger) Critical(format string, v ...interface{}) {
 		return
 	}
 	msg := fmt.Sprintf("[C] "+format, v...)
-	bl.writerMsg(LevelCritical, msg)
+	bl.writerMsg(LevelCritical, msg, 0)
 }
 
 // Log ERROR level message.
@@ -258,7 +485,7 @@ func (bl *XLogger) Error(format string, v ...interface{}) {
 		return
 	}
 	msg := fmt.Sprintf("[E] "+format, v...)
-	bl.writerMsg(LevelError, msg)
+	bl.writerMsg(LevelError, msg, 0)
 }
 
 // Log WARNING level message.
@@ -267,7 +494,7 @@ func (bl *XLogger) Warning(format string, v ...interface{}) {
 		return
 	}
 	msg := fmt.Sprintf("[W] "+format, v...)
-	bl.writerMsg(LevelWarning, msg)
+	bl.writerMsg(LevelWarning, msg, 0)
 }
 
 // Log NOTICE level message.
@@ -276,7 +503,7 @@ func (bl *XLogger) Notice(format string, v ...interface{}) {
 		return
 	}
 	msg := fmt.Sprintf("[N] "+format, v...)
-	bl.writerMsg(LevelNotice, msg)
+	bl.writerMsg(LevelNotice, msg, 0)
 }
 
 // Log INFORMATIONAL level message.
@@ -285,7 +512,7 @@ func (bl *XLogger) Informational(format string, v ...interface{}) {
 		return
 	}
 	msg := fmt.Sprintf("[I] "+format, v...)
-	bl.writerMsg(LevelInformational, msg)
+	bl.writerMsg(LevelInformational, msg, 0)
 }
 
 // Log DEBUG level message.
@@ -294,7 +521,7 @@ func (bl *XLogger) Debug(format string, v ...interface{}) {
 		return
 	}
 	msg := fmt.Sprintf("[D] "+format, v...)
-	bl.writerMsg(LevelDebug, msg)
+	bl.writerMsg(LevelDebug, msg, 0)
 }
 
 // Log WARN level message.
@@ -304,7 +531,7 @@ func (bl *XLogger) Warn(format string, v ...interface{}) {
 		return
 	}
 	msg := fmt.Sprintf("[W] "+format, v...)
-	bl.writerMsg(LevelWarning, msg)
+	bl.writerMsg(LevelWarning, msg, 0)
 }
 
 // Log INFO level message.
@@ -314,7 +541,7 @@ func (bl *XLogger) Info(format string, v ...interface{}) {
 		return
 	}
 	msg := fmt.Sprintf("[I] "+format, v...)
-	bl.writerMsg(LevelInformational, msg)
+	bl.writerMsg(LevelInformational, msg, 0)
 }
 
 // Log TRACE level message.
@@ -324,11 +551,14 @@ func (bl *XLogger) Trace(format string, v ...interface{}) {
 		return
 	}
 	msg := fmt.Sprintf("[D] "+format, v...)
-	bl.writerMsg(LevelDebug, msg)
+	bl.writerMsg(LevelDebug, msg, 0)
 }
 
 // flush all chan data.
 func (bl *XLogger) Flush() {
+	if bl.asynchronous {
+		bl.drain.wait()
+	}
 	for _, l := range bl.outputs {
 		l.Flush()
 	}
@@ -336,21 +566,12 @@ func (bl *XLogger) Flush() {
 
 // close logger, flush all chan data and destroy all adapters in XLogger.
 func (bl *XLogger) Close() {
-	for {
-		if len(bl.msg) > 0 {
-			bm := <-bl.msg
-			for _, l := range bl.outputs {
-				err := l.WriteMsg(bm.msg, bm.level)
-				if err != nil {
-					fmt.Println("ERROR, unable to WriteMsg (while closing logger):", err)
-				}
-			}
-			continue
-		}
-		break
+	if bl.asynchronous {
+		bl.drain.wait()
+		close(bl.signalChan)
 	}
 	for _, l := range bl.outputs {
 		l.Flush()
 		l.Destroy()
 	}
-}
\ No newline at end of file
+}
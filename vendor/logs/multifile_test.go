@@ -0,0 +1,60 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMultiFileWriterRoutesUnlistedLevelsToBaseFile(t *testing.T) {
+	dir := t.TempDir()
+	logfile := filepath.Join(dir, "app.log")
+
+	w := NewMultiFileWriter().(*multiFileWriter)
+	cfg := fmt.Sprintf(`{"filename":%q,"separate":["error","info","debug"]}`, logfile)
+	if err := w.Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Destroy()
+
+	// Warning isn't in "separate", so it must still land somewhere instead
+	// of being silently dropped.
+	if err := w.WriteMsg("uh oh", LevelWarning); err != nil {
+		t.Fatal(err)
+	}
+	w.Flush()
+
+	data, err := os.ReadFile(logfile)
+	if err != nil {
+		t.Fatalf("expected base file %s to exist and contain the unlisted-level message: %v", logfile, err)
+	}
+	if len(data) == 0 {
+		t.Fatal("base file is empty, want the Warning message to have been written")
+	}
+}
+
+func TestMultiFileWriterInitRejectsEmptyConfigCleanly(t *testing.T) {
+	w := NewMultiFileWriter().(*multiFileWriter)
+	err := w.Init("")
+	if err == nil {
+		t.Fatal("expected an error for an empty config with no filename")
+	}
+	if err.Error() != "logs.multiFileWriter: filename cannot be empty" {
+		t.Fatalf("err = %q, want the adapter's own clear message, not a raw json error", err.Error())
+	}
+}
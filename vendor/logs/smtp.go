@@ -0,0 +1,137 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+)
+
+// smtpWriter batches messages at or above Level and emails them as one
+// message via net/smtp, instead of sending one email per log line. It is
+// registered under the name "smtp".
+type smtpWriter struct {
+	Username  string   `json:"username"`
+	Password  string   `json:"password"`
+	Host      string   `json:"host"`
+	Subject   string   `json:"subject"`
+	SendTos   []string `json:"sendTos"`
+	Level     int      `json:"level"`
+	BatchSize int      `json:"batchSize"`
+	Format    string   `json:"format"`
+
+	formatter Formatter
+
+	mu     sync.Mutex
+	buffer bytes.Buffer
+	count  int
+}
+
+// NewSMTPWriter creates an smtp adapter. It defaults to only batching
+// error-and-above messages, since mailing every Info line would be noise.
+func NewSMTPWriter() XLoggerInterface {
+	return &smtpWriter{Level: LevelError, formatter: TextFormatter{}}
+}
+
+func (s *smtpWriter) Init(jsonconfig string) error {
+	if len(jsonconfig) > 0 {
+		if err := json.Unmarshal([]byte(jsonconfig), s); err != nil {
+			return err
+		}
+	}
+	if s.Format != "" {
+		f, err := ParseFormatter(s.Format)
+		if err != nil {
+			return err
+		}
+		s.formatter = f
+	}
+	return nil
+}
+
+func (s *smtpWriter) WriteMsg(msg string, level int) error {
+	if level > s.Level {
+		return nil
+	}
+	s.append([]byte(msg))
+	return nil
+}
+
+// WriteRecord implements StructuredWriter.
+func (s *smtpWriter) WriteRecord(rec *LogRecord) error {
+	if rec.Level > s.Level {
+		return nil
+	}
+	s.append(s.formatter.Format(rec))
+	return nil
+}
+
+func (s *smtpWriter) append(b []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buffer.Write(b)
+	s.buffer.WriteByte('\n')
+	s.count++
+	if s.BatchSize > 0 && s.count >= s.BatchSize {
+		s.flushLocked()
+	}
+}
+
+// Flush sends whatever is currently buffered as a single email.
+func (s *smtpWriter) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flushLocked()
+}
+
+func (s *smtpWriter) flushLocked() {
+	if s.buffer.Len() == 0 {
+		return
+	}
+	if err := s.sendMail(s.buffer.Bytes()); err != nil {
+		fmt.Fprintln(os.Stderr, "logs.smtpWriter: send mail failed:", err)
+	}
+	s.buffer.Reset()
+	s.count = 0
+}
+
+func (s *smtpWriter) sendMail(body []byte) error {
+	host := s.Host
+	if idx := strings.IndexByte(host, ':'); idx >= 0 {
+		host = host[:idx]
+	}
+	auth := smtp.PlainAuth("", s.Username, s.Password, host)
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.SendTos, ";"))
+	fmt.Fprintf(&msg, "From: %s\r\n", s.Username)
+	fmt.Fprintf(&msg, "Subject: %s\r\n\r\n", s.Subject)
+	msg.Write(body)
+
+	return smtp.SendMail(s.Host, auth, s.Username, s.SendTos, msg.Bytes())
+}
+
+func (s *smtpWriter) Destroy() {
+	s.Flush()
+}
+
+func init() {
+	Register("smtp", NewSMTPWriter)
+}